@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"sync"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+// transcriptMessage is what a session sends to the browser for each
+// transcript update, replacing the bare transcript string the starter used
+// before diarization/multichannel support existed.
+type transcriptMessage struct {
+	Type    string `json:"type"`
+	Speaker int    `json:"speaker"`
+	Channel int    `json:"channel"`
+	IsFinal bool   `json:"isFinal"`
+	Text    string `json:"text"`
+}
+
+// speakerRun is one contiguous stretch of words spoken by the same speaker
+// within a single MessageResponse.
+type speakerRun struct {
+	speaker int
+	text    string
+}
+
+// groupWordsBySpeaker splits words into runs of consecutive same-speaker
+// words. When diarization isn't enabled (or Deepgram didn't return
+// word-level detail), there's no Speaker to split on, so it falls back to a
+// single run using the alternative's transcript as-is.
+func groupWordsBySpeaker(words []api.Word, fallback string) []speakerRun {
+	if len(words) == 0 {
+		return []speakerRun{{speaker: 0, text: fallback}}
+	}
+
+	var runs []speakerRun
+	var cur *speakerRun
+	for _, w := range words {
+		speaker := 0
+		if w.Speaker != nil {
+			speaker = *w.Speaker
+		}
+
+		word := w.PunctuatedWord
+		if word == "" {
+			word = w.Word
+		}
+
+		if cur == nil || cur.speaker != speaker {
+			if cur != nil {
+				runs = append(runs, *cur)
+			}
+			cur = &speakerRun{speaker: speaker}
+		}
+		if cur.text != "" {
+			cur.text += " "
+		}
+		cur.text += word
+	}
+	if cur != nil {
+		runs = append(runs, *cur)
+	}
+	return runs
+}
+
+// speakerChannel identifies one party in a multi-speaker, multi-channel
+// conversation.
+type speakerChannel struct {
+	Speaker int
+	Channel int
+}
+
+// speakerAggregator reassembles per-speaker sentences across the interim/
+// final message boundaries Deepgram sends, so the UI can render one
+// coherent line per speaker turn rather than a flood of partial updates.
+// UtteranceEnd flushes whatever is currently buffered, mirroring how a
+// pause in speech ends a turn.
+type speakerAggregator struct {
+	mu      sync.Mutex
+	buffers map[speakerChannel]*strings.Builder
+}
+
+func newSpeakerAggregator() *speakerAggregator {
+	return &speakerAggregator{buffers: make(map[speakerChannel]*strings.Builder)}
+}
+
+// append adds a final word run's text to the buffer for its speaker/channel.
+func (a *speakerAggregator) append(key speakerChannel, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, ok := a.buffers[key]
+	if !ok {
+		b = &strings.Builder{}
+		a.buffers[key] = b
+	}
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	b.WriteString(text)
+}
+
+// flushAll returns and clears every buffered speaker/channel sentence.
+func (a *speakerAggregator) flushAll() map[speakerChannel]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make(map[speakerChannel]string, len(a.buffers))
+	for key, b := range a.buffers {
+		if s := strings.TrimSpace(b.String()); s != "" {
+			out[key] = s
+		}
+	}
+	a.buffers = make(map[speakerChannel]*strings.Builder)
+	return out
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestGroupWordsBySpeaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []api.Word
+		fallback string
+		want     []speakerRun
+	}{
+		{
+			name:     "no words falls back to the bare transcript",
+			words:    nil,
+			fallback: "hello there",
+			want:     []speakerRun{{speaker: 0, text: "hello there"}},
+		},
+		{
+			name: "single speaker",
+			words: []api.Word{
+				{PunctuatedWord: "Hello,", Speaker: intPtr(0)},
+				{PunctuatedWord: "world.", Speaker: intPtr(0)},
+			},
+			want: []speakerRun{{speaker: 0, text: "Hello, world."}},
+		},
+		{
+			name: "splits on speaker change",
+			words: []api.Word{
+				{PunctuatedWord: "Hi", Speaker: intPtr(0)},
+				{PunctuatedWord: "there.", Speaker: intPtr(0)},
+				{PunctuatedWord: "Hey", Speaker: intPtr(1)},
+				{PunctuatedWord: "back.", Speaker: intPtr(1)},
+				{PunctuatedWord: "OK.", Speaker: intPtr(0)},
+			},
+			want: []speakerRun{
+				{speaker: 0, text: "Hi there."},
+				{speaker: 1, text: "Hey back."},
+				{speaker: 0, text: "OK."},
+			},
+		},
+		{
+			name: "missing speaker falls back to 0",
+			words: []api.Word{
+				{Word: "hi"},
+			},
+			want: []speakerRun{{speaker: 0, text: "hi"}},
+		},
+		{
+			name: "falls back to raw Word when PunctuatedWord is empty",
+			words: []api.Word{
+				{Word: "hi", Speaker: intPtr(0)},
+			},
+			want: []speakerRun{{speaker: 0, text: "hi"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupWordsBySpeaker(tt.words, tt.fallback)
+			if len(got) != len(tt.want) {
+				t.Fatalf("groupWordsBySpeaker() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("run %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSpeakerAggregator(t *testing.T) {
+	a := newSpeakerAggregator()
+	speaker0 := speakerChannel{Speaker: 0, Channel: 0}
+	speaker1 := speakerChannel{Speaker: 1, Channel: 0}
+
+	a.append(speaker0, "Hello")
+	a.append(speaker0, "world.")
+	a.append(speaker1, "Hi back.")
+
+	got := a.flushAll()
+	if got[speaker0] != "Hello world." {
+		t.Errorf("speaker0 = %q, want %q", got[speaker0], "Hello world.")
+	}
+	if got[speaker1] != "Hi back." {
+		t.Errorf("speaker1 = %q, want %q", got[speaker1], "Hi back.")
+	}
+
+	// flushAll clears the buffers; appending an empty/whitespace-only run
+	// afterwards shouldn't resurrect an entry for a speaker that said nothing.
+	a.append(speaker0, "   ")
+	if got := a.flushAll(); len(got) != 0 {
+		t.Errorf("flushAll() after empty append = %+v, want empty", got)
+	}
+}
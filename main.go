@@ -2,13 +2,11 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
+	"strconv"
 
-	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
 	interfaces "github.com/deepgram/deepgram-go-sdk/pkg/client/interfaces"
 	client "github.com/deepgram/deepgram-go-sdk/pkg/client/live"
 
@@ -20,59 +18,8 @@ var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
-type WebSocketMessage struct {
-	Type string `json:"type"`
-}
-
-// Implement your own callback
-type MyCallback struct {
-	socket *websocket.Conn
-}
-
-// Initialize the channel in the MyCallback struct
-func NewMyCallback(conn *websocket.Conn) *MyCallback {
-	return &MyCallback{
-		socket: conn,
-	}
-}
-
-func (c *MyCallback) Message(mr *api.MessageResponse) error {
-	sentence := strings.TrimSpace(mr.Channel.Alternatives[0].Transcript)
-
-	if len(mr.Channel.Alternatives) == 0 || len(sentence) == 0 {
-		return nil
-	}
-	fmt.Printf("\n%s\n\n", sentence)
-	c.socket.WriteJSON(sentence)
-
-	return nil
-}
-
-func (c MyCallback) Metadata(md *api.MetadataResponse) error {
-	// handle the metadata
-	fmt.Printf("\n[Metadata] Received\n")
-	fmt.Printf("Metadata.RequestID: %s\n", strings.TrimSpace(md.RequestID))
-	fmt.Printf("Metadata.Channels: %d\n", md.Channels)
-	fmt.Printf("Metadata.Created: %s\n\n", strings.TrimSpace(md.Created))
-	return nil
-}
-
-func (c MyCallback) UtteranceEnd(ur *api.UtteranceEndResponse) error {
-	fmt.Printf("\n[UtteranceEnd] Received\n")
-	return nil
-}
-
-func (c MyCallback) Error(er *api.ErrorResponse) error {
-	// handle the error
-	fmt.Printf("\n[Error] Received\n")
-	fmt.Printf("Error.Type: %s\n", er.Type)
-	fmt.Printf("Error.Message: %s\n", er.Message)
-	fmt.Printf("Error.Description: %s\n\n", er.Description)
-	return nil
-}
-
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil) // pass w into new mycallback
+	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		fmt.Println("WebSocket upgrade failed:", err)
 		return
@@ -83,79 +30,101 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	ctx := context.Background()
 	// set the Transcription options
 	transcriptOptions := interfaces.LiveTranscriptionOptions{
-		Language:    "en-US",
-		Model:       "nova-2",
-		SmartFormat: true,
+		Language:     "en-US",
+		Model:        "nova-2",
+		SmartFormat:  true,
+		Diarize:      true,
+		Multichannel: true,
+		// UtteranceEnd (the aggregator's flush trigger) is only emitted by
+		// Deepgram when interim results are on and an utterance_end_ms is set.
+		InterimResults: true,
+		UtteranceEndMs: "1000",
 	}
 
-	clientOptions := interfaces.ClientOptions{
-		// EnableKeepAlive: true,
+	// set the TTS options for speaking agent replies back to the browser
+	speakOptions := interfaces.WSSpeakOptions{
+		Model:    "aura-asteria-en",
+		Encoding: "linear16",
 	}
 
-	apiKey := os.Getenv("DEEPGRAM_API_KEY")
-	callback := NewMyCallback(conn)
+	clientOptions := interfaces.ClientOptions{
+		AutoFlushReplyDelta: autoFlushReplyDeltaMs(),
+		// Keeps the connection alive with periodic KeepAlive messages so
+		// load balancers/proxies between us and Deepgram don't cull it
+		// during quiet stretches, independent of our own reconnect logic.
+		EnableKeepAlive: true,
+	}
 
-	dgClient, err := client.New(ctx, apiKey, &clientOptions, transcriptOptions, callback)
-	// dgClient, err := client.NewWithDefaults(ctx, transcriptOptions, callback)
+	sink, err := newTranscriptSinkFromEnv()
 	if err != nil {
-		fmt.Println("ERROR creating LiveTranscription connection:", err)
+		fmt.Println("ERROR configuring transcript sink:", err)
+		conn.Close()
 		return
 	}
 
-	// connect the websocket to Deepgram
-	wsconn := dgClient.Connect()
-	if wsconn == nil {
-		fmt.Println("Client.Connect failed")
-		os.Exit(1)
-	}
-
+	s, err := newSession(ctx, conn, clientOptions, transcriptOptions, speakOptions, sink)
 	if err != nil {
-		// Handle error
+		fmt.Println("ERROR starting session:", err)
+		sink.Close()
+		conn.Close()
 		return
 	}
-	// defer conn.Close()
-	var clientMsg WebSocketMessage
-	// Set up a loop to continuously read messages from the WebSocket
-	for {
-		select {
-		// Send the transcription to the client
-		default:
-			// Handle other WebSocket messages or events
-			messageType, p, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseGoingAway) {
-					fmt.Println("Client closed connection (going away)")
-					return
-				}
-				fmt.Println("Error reading WebSocket message:", err)
-				return
-			}
-			if messageType == websocket.BinaryMessage {
-				// Send the received message to Deepgram
-				n, err := dgClient.Write(p)
-				if err != nil {
-					fmt.Println("Error sending data to Deepgram:", err)
-					// Handle the error as needed
-				}
-				fmt.Printf("WebSocket: %d bytes from client \n", n)
-			} else if messageType == websocket.TextMessage {
-
-				err := json.Unmarshal(p, &clientMsg)
-				if err != nil {
-					fmt.Println("Error decoding JSON:", err)
-					continue
-				}
-				fmt.Printf("WebSocket: %s\n", clientMsg.Type)
-
-				if clientMsg.Type == "closeMicrophone" { // Will need to reconnect in the UI
-					// Close the connection to Deepgram
-					dgClient.Stop()
-					// fmt.Println("WebSocket: closed connection to Deepgram")
-					return
-				}
-			}
+
+	s.run()
+}
+
+// newTranscriptSinkFromEnv builds the TranscriptSink selected by
+// TRANSCRIPT_SINK ("jsonl", "vtt", "srt", or "webhook"); unset or
+// unrecognized selects noopSink.
+//
+//   - jsonl:   TRANSCRIPT_SINK_PATH (default "transcript.jsonl")
+//   - vtt/srt: TRANSCRIPT_SINK_PATH (default "transcript.<ext>")
+//   - webhook: TRANSCRIPT_WEBHOOK_URL (required), TRANSCRIPT_WEBHOOK_SECRET (optional, enables HMAC signing)
+func newTranscriptSinkFromEnv() (TranscriptSink, error) {
+	switch os.Getenv("TRANSCRIPT_SINK") {
+	case "jsonl":
+		path := os.Getenv("TRANSCRIPT_SINK_PATH")
+		if path == "" {
+			path = "transcript.jsonl"
 		}
+		return newJSONLSink(path)
+	case "vtt":
+		path := os.Getenv("TRANSCRIPT_SINK_PATH")
+		if path == "" {
+			path = "transcript.vtt"
+		}
+		return newCaptionSink(path, captionFormatVTT)
+	case "srt":
+		path := os.Getenv("TRANSCRIPT_SINK_PATH")
+		if path == "" {
+			path = "transcript.srt"
+		}
+		return newCaptionSink(path, captionFormatSRT)
+	case "webhook":
+		url := os.Getenv("TRANSCRIPT_WEBHOOK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("TRANSCRIPT_WEBHOOK_URL is required when TRANSCRIPT_SINK=webhook")
+		}
+		return newWebhookSink(url, os.Getenv("TRANSCRIPT_WEBHOOK_SECRET")), nil
+	default:
+		return noopSink{}, nil
+	}
+}
+
+// autoFlushReplyDeltaMs reads AUTO_FLUSH_REPLY_DELTA_MS, the idle duration
+// (in milliseconds) after which a quiet browser socket triggers a Deepgram
+// Finalize. 0 (the default) disables auto-finalize.
+func autoFlushReplyDeltaMs() int64 {
+	v := os.Getenv("AUTO_FLUSH_REPLY_DELTA_MS")
+	if v == "" {
+		return 0
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		fmt.Println("Invalid AUTO_FLUSH_REPLY_DELTA_MS, ignoring:", err)
+		return 0
 	}
+	return ms
 }
 
 func main() {
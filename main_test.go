@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestAutoFlushReplyDeltaMs(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want int64
+	}{
+		{name: "unset disables auto-finalize", env: "", want: 0},
+		{name: "valid value is parsed", env: "1500", want: 1500},
+		{name: "invalid value falls back to disabled", env: "not-a-number", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTO_FLUSH_REPLY_DELTA_MS", tt.env)
+			if got := autoFlushReplyDeltaMs(); got != tt.want {
+				t.Errorf("autoFlushReplyDeltaMs() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
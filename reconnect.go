@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	listen "github.com/deepgram/deepgram-go-sdk/pkg/client/live"
+)
+
+const (
+	reconnectInitialBackoff = 250 * time.Millisecond
+	reconnectMaxBackoff     = 5 * time.Second
+	reconnectMaxAttempts    = 8
+
+	// reconnectRingSize bounds how many recent browser audio frames we hold
+	// onto so they can be replayed against a freshly reconnected listen
+	// client; it's not a transcript buffer, just enough to cover a blip.
+	reconnectRingSize = 200
+)
+
+// frameRing is a fixed-capacity FIFO of the most recent binary frames
+// received from the browser, used to replay audio that arrived while the
+// listen client was reconnecting.
+type frameRing struct {
+	mu     sync.Mutex
+	frames [][]byte
+	cap    int
+}
+
+func newFrameRing(capacity int) *frameRing {
+	return &frameRing{cap: capacity}
+}
+
+func (r *frameRing) push(frame []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf := make([]byte, len(frame))
+	copy(buf, frame)
+	r.frames = append(r.frames, buf)
+	if len(r.frames) > r.cap {
+		r.frames = r.frames[len(r.frames)-r.cap:]
+	}
+}
+
+// drain returns and clears the buffered frames.
+func (r *frameRing) drain() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	frames := r.frames
+	r.frames = nil
+	return frames
+}
+
+// triggerReconnect kicks off a supervised reconnect in the background. It's
+// safe to call repeatedly (from the listen callback's Error and Close
+// methods, which can both fire for the same drop); only one reconnect loop
+// ever runs at a time.
+func (s *session) triggerReconnect() {
+	if atomic.LoadInt32(&s.stopping) == 1 {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.reconnecting, 0, 1) {
+		return
+	}
+
+	s.dgMu.Lock()
+	s.dgConnected = false
+	s.dgMu.Unlock()
+
+	go s.reconnectListen()
+}
+
+// reconnectListen re-establishes the listen client with exponential
+// backoff, replays whatever browser audio arrived during the outage, and
+// reports status to the browser so the UI can show a degraded state.
+func (s *session) reconnectListen() {
+	defer atomic.StoreInt32(&s.reconnecting, 0)
+
+	s.browser.WriteJSON(browserMessage{Type: "reconnecting"})
+
+	backoff := reconnectInitialBackoff
+	for attempt := 1; attempt <= reconnectMaxAttempts; attempt++ {
+		if atomic.LoadInt32(&s.stopping) == 1 {
+			return
+		}
+
+		dgClient, err := listen.New(s.ctx, s.apiKey, &s.clientOptions, &s.transcriptOptions, newMyCallback(s.browser, s))
+		if err == nil && dgClient.Connect() {
+			if atomic.LoadInt32(&s.stopping) == 1 {
+				dgClient.Stop()
+				return
+			}
+
+			s.dgMu.Lock()
+			old := s.dg
+			s.dg = dgClient
+			s.dgConnected = true
+			s.dgMu.Unlock()
+			old.Stop()
+
+			for _, frame := range s.recent.drain() {
+				if _, err := dgClient.Write(frame); err != nil {
+					fmt.Println("Error replaying buffered audio to Deepgram:", err)
+					break
+				}
+			}
+
+			s.browser.WriteJSON(browserMessage{Type: "reconnected"})
+			return
+		}
+		fmt.Printf("Reconnect attempt %d/%d failed: %v\n", attempt, reconnectMaxAttempts, err)
+
+		jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+		time.Sleep(backoff + jitter)
+
+		backoff *= 2
+		if backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+
+	fmt.Println("Giving up on reconnecting to Deepgram after", reconnectMaxAttempts, "attempts")
+	s.browser.WriteJSON(browserMessage{Type: "reconnectFailed"})
+}
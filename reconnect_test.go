@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFrameRingPushDrain(t *testing.T) {
+	r := newFrameRing(3)
+
+	r.push([]byte("a"))
+	r.push([]byte("b"))
+
+	got := r.drain()
+	if len(got) != 2 || !bytes.Equal(got[0], []byte("a")) || !bytes.Equal(got[1], []byte("b")) {
+		t.Fatalf("drain() = %v, want [a b]", got)
+	}
+
+	// drain empties the ring.
+	if got := r.drain(); len(got) != 0 {
+		t.Fatalf("drain() after drain = %v, want empty", got)
+	}
+}
+
+func TestFrameRingWraparound(t *testing.T) {
+	r := newFrameRing(2)
+
+	r.push([]byte("a"))
+	r.push([]byte("b"))
+	r.push([]byte("c"))
+
+	got := r.drain()
+	if len(got) != 2 || !bytes.Equal(got[0], []byte("b")) || !bytes.Equal(got[1], []byte("c")) {
+		t.Fatalf("drain() = %v, want [b c] (oldest frame evicted)", got)
+	}
+}
+
+func TestFrameRingPushCopiesInput(t *testing.T) {
+	r := newFrameRing(1)
+
+	frame := []byte("a")
+	r.push(frame)
+	frame[0] = 'z' // mutating the caller's slice after push must not affect the buffered copy
+
+	got := r.drain()
+	if !bytes.Equal(got[0], []byte("a")) {
+		t.Fatalf("drain() = %v, want [a] (push should copy its input)", got)
+	}
+}
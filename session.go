@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+	speakapi "github.com/deepgram/deepgram-go-sdk/pkg/api/speak/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/pkg/client/interfaces"
+	listen "github.com/deepgram/deepgram-go-sdk/pkg/client/live"
+	speak "github.com/deepgram/deepgram-go-sdk/pkg/client/speak/v1/websocket"
+
+	"github.com/gorilla/websocket"
+)
+
+// Binary frames written to the browser socket are prefixed with one of these
+// markers so the client-side JS can route them without inspecting payload
+// contents. Today only TTS audio flows in that direction.
+const (
+	frameKindTTSAudio byte = 0x01
+)
+
+// browserMessage is the JSON control protocol spoken on the browser socket
+// for anything that isn't raw PCM audio. "speak" submits text to be
+// synthesized and played back; "flush"/"clear" mirror the Deepgram speak
+// websocket's buffer controls; "closeMicrophone" tears the session down.
+type browserMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// safeConn serializes writes to the browser socket. gorilla/websocket only
+// allows one concurrent writer per connection, and a session now has two
+// goroutines (the listen callback and the speak callback) that can each
+// produce output for the browser at the same time.
+type safeConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *safeConn) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+func (c *safeConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// session wires one browser connection to a Deepgram listen client (speech
+// to text) and a Deepgram speak client (text to speech), so that an agent
+// reply can be spoken back over the same socket the microphone audio
+// streamed in on. It also supervises the listen client: if it drops
+// mid-conversation, the session reconnects it behind the scenes instead of
+// tearing down the browser socket.
+type session struct {
+	browser *safeConn
+
+	ctx               context.Context
+	apiKey            string
+	clientOptions     interfaces.ClientOptions
+	transcriptOptions interfaces.LiveTranscriptionOptions
+
+	dgMu        sync.RWMutex
+	dg          *listen.Client
+	dgConnected bool
+
+	reconnecting int32 // atomic bool; guards against overlapping reconnect attempts
+	stopping     int32 // atomic bool; set before an intentional shutdown so a resulting Error/Close isn't mistaken for a drop
+	recent       *frameRing
+
+	aggregator *speakerAggregator
+	sink       TranscriptSink
+
+	speak *speak.Client
+}
+
+// newSession creates and connects the paired listen/speak Deepgram clients
+// for a single browser connection. clientOptions is shared between both
+// clients; in particular setting AutoFlushReplyDelta (milliseconds) makes
+// the listen client send Deepgram a Finalize control message whenever the
+// browser has gone quiet for that long, giving push-to-talk UIs a reliable
+// end-of-utterance signal without depending on VAD, and EnableKeepAlive
+// keeps idle connections from being culled by intermediaries.
+func newSession(ctx context.Context, conn *websocket.Conn, clientOptions interfaces.ClientOptions, transcriptOptions interfaces.LiveTranscriptionOptions, speakOptions interfaces.WSSpeakOptions, sink TranscriptSink) (*session, error) {
+	apiKey := os.Getenv("DEEPGRAM_API_KEY")
+	browser := &safeConn{conn: conn}
+
+	if sink == nil {
+		sink = noopSink{}
+	}
+
+	s := &session{
+		browser:           browser,
+		ctx:               ctx,
+		apiKey:            apiKey,
+		clientOptions:     clientOptions,
+		transcriptOptions: transcriptOptions,
+		recent:            newFrameRing(reconnectRingSize),
+		aggregator:        newSpeakerAggregator(),
+		sink:              sink,
+	}
+
+	dgClient, err := listen.New(ctx, apiKey, &clientOptions, &transcriptOptions, newMyCallback(browser, s))
+	if err != nil {
+		return nil, fmt.Errorf("creating listen client: %w", err)
+	}
+	if !dgClient.Connect() {
+		return nil, fmt.Errorf("listen client: connect failed")
+	}
+	s.dg = dgClient
+	s.dgConnected = true
+
+	speakClient, err := speak.NewUsingCallback(ctx, apiKey, &clientOptions, &speakOptions, newTTSCallback(browser))
+	if err != nil {
+		// Mark stopping first: dgClient.Stop() synchronously closes the
+		// listen callback, which would otherwise read this as an
+		// unexpected drop and spin up a reconnect loop for a session
+		// newSession is about to report as failed.
+		atomic.StoreInt32(&s.stopping, 1)
+		dgClient.Stop()
+		return nil, fmt.Errorf("creating speak client: %w", err)
+	}
+	if !speakClient.Connect() {
+		atomic.StoreInt32(&s.stopping, 1)
+		dgClient.Stop()
+		return nil, fmt.Errorf("speak client: connect failed")
+	}
+	s.speak = speakClient
+
+	return s, nil
+}
+
+// run reads the browser socket until it closes or asks to close the
+// microphone, forwarding binary audio frames to Deepgram for transcription
+// and dispatching the JSON control protocol (speak/flush/clear) to the
+// speak client.
+func (s *session) run() {
+	// Deferred LIFO: registered first so it runs last, after both Deepgram
+	// clients have actually stopped and can no longer deliver callbacks
+	// that write to the sink.
+	defer s.sink.Close()
+	defer func() {
+		atomic.StoreInt32(&s.stopping, 1)
+		s.dgMu.RLock()
+		dg := s.dg
+		s.dgMu.RUnlock()
+		dg.Stop()
+	}()
+	defer s.speak.Stop()
+
+	for {
+		messageType, p, err := s.browser.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseGoingAway) {
+				fmt.Println("Client closed connection (going away)")
+				return
+			}
+			fmt.Println("Error reading WebSocket message:", err)
+			return
+		}
+
+		switch messageType {
+		case websocket.BinaryMessage:
+			s.writeAudio(p)
+		case websocket.TextMessage:
+			var msg browserMessage
+			if err := json.Unmarshal(p, &msg); err != nil {
+				fmt.Println("Error decoding JSON:", err)
+				continue
+			}
+			fmt.Printf("WebSocket: %s\n", msg.Type)
+
+			if !s.handleControlMessage(msg) {
+				return
+			}
+		}
+	}
+}
+
+// writeAudio forwards p to Deepgram if the listen client is currently
+// connected. A write failure or an in-flight reconnect buffers the frame in
+// the ring of recent frames for replay once the listen client reconnects.
+func (s *session) writeAudio(p []byte) {
+	s.dgMu.RLock()
+	dg, connected := s.dg, s.dgConnected
+	s.dgMu.RUnlock()
+	if !connected {
+		s.recent.push(p)
+		return
+	}
+
+	if _, err := dg.Write(p); err != nil {
+		fmt.Println("Error sending data to Deepgram:", err)
+		s.recent.push(p)
+		s.triggerReconnect()
+		return
+	}
+	fmt.Printf("WebSocket: %d bytes from client \n", len(p))
+}
+
+// handleControlMessage dispatches one browserMessage. It returns false when
+// the session should stop reading from the browser.
+func (s *session) handleControlMessage(msg browserMessage) bool {
+	switch msg.Type {
+	case "closeMicrophone": // Will need to reconnect in the UI
+		return false
+	case "speak":
+		if err := s.speak.SpeakWithText(msg.Text); err != nil {
+			fmt.Println("Error submitting text to speak client:", err)
+		}
+	case "flush":
+		if err := s.speak.Flush(); err != nil {
+			fmt.Println("Error flushing speak client:", err)
+		}
+	case "clear":
+		if err := s.speak.Reset(); err != nil {
+			fmt.Println("Error clearing speak client buffer:", err)
+		}
+	}
+	return true
+}
+
+// ttsCallback forwards Deepgram speak websocket events to the browser,
+// tagging synthesized audio frames with frameKindTTSAudio so the client JS
+// can route them to an AudioContext for playback.
+type ttsCallback struct {
+	browser *safeConn
+}
+
+func newTTSCallback(browser *safeConn) *ttsCallback {
+	return &ttsCallback{browser: browser}
+}
+
+func (c *ttsCallback) Open(or *speakapi.OpenResponse) error {
+	fmt.Println("\n[Speak] connection opened")
+	return nil
+}
+
+func (c *ttsCallback) Metadata(md *speakapi.MetadataResponse) error {
+	fmt.Printf("\n[Speak] Metadata.RequestID: %s\n", md.RequestID)
+	return nil
+}
+
+func (c *ttsCallback) Flush(fr *speakapi.FlushedResponse) error {
+	return c.browser.WriteJSON(browserMessage{Type: "flushed"})
+}
+
+func (c *ttsCallback) Clear(cr *speakapi.ClearedResponse) error {
+	return c.browser.WriteJSON(browserMessage{Type: "cleared"})
+}
+
+func (c *ttsCallback) Close(cr *speakapi.CloseResponse) error {
+	fmt.Println("\n[Speak] connection closed")
+	return nil
+}
+
+func (c *ttsCallback) Warning(wr *speakapi.WarningResponse) error {
+	fmt.Printf("\n[Speak Warning] %s: %s\n", wr.WarnCode, wr.WarnMsg)
+	return nil
+}
+
+func (c *ttsCallback) Error(er *speakapi.ErrorResponse) error {
+	fmt.Printf("\n[Speak Error] %s: %s\n", er.ErrCode, er.ErrMsg)
+	return nil
+}
+
+func (c *ttsCallback) UnhandledEvent(byData []byte) error {
+	fmt.Printf("\n[Speak] Unhandled event: %s\n", string(byData))
+	return nil
+}
+
+// Binary is called with each chunk of synthesized PCM/Opus audio as it
+// arrives from Deepgram; it's relayed to the browser prefixed with
+// frameKindTTSAudio.
+func (c *ttsCallback) Binary(byData []byte) error {
+	frame := make([]byte, len(byData)+1)
+	frame[0] = frameKindTTSAudio
+	copy(frame[1:], byData)
+	return c.browser.WriteMessage(websocket.BinaryMessage, frame)
+}
+
+// MyCallback implements the Deepgram listen websocket callback, forwarding
+// transcripts to the browser and notifying its owning session when the
+// Deepgram connection reports an error so the session can reconnect.
+type MyCallback struct {
+	socket  *safeConn
+	session *session
+}
+
+// newMyCallback creates a MyCallback that writes to conn and reports drops to sess
+func newMyCallback(conn *safeConn, sess *session) *MyCallback {
+	return &MyCallback{
+		socket:  conn,
+		session: sess,
+	}
+}
+
+func (c *MyCallback) Message(mr *api.MessageResponse) error {
+	if len(mr.Channel.Alternatives) == 0 {
+		return nil
+	}
+
+	alt := mr.Channel.Alternatives[0]
+	if len(alt.Transcript) == 0 {
+		return nil
+	}
+
+	channel := 0
+	if len(mr.ChannelIndex) > 0 {
+		channel = mr.ChannelIndex[0]
+	}
+
+	for _, run := range groupWordsBySpeaker(alt.Words, alt.Transcript) {
+		fmt.Printf("\n[speaker %d, channel %d] %s\n\n", run.speaker, channel, run.text)
+		c.socket.WriteJSON(transcriptMessage{
+			Type:    "transcript",
+			Speaker: run.speaker,
+			Channel: channel,
+			IsFinal: mr.IsFinal,
+			Text:    run.text,
+		})
+
+		if mr.IsFinal {
+			c.session.aggregator.append(speakerChannel{Speaker: run.speaker, Channel: channel}, run.text)
+		}
+	}
+
+	if sinkErr := c.sinkFor(mr); sinkErr != nil {
+		fmt.Println("Error writing to transcript sink:", sinkErr)
+	}
+
+	return nil
+}
+
+// sinkFor forwards mr to the session's TranscriptSink as an interim or
+// final event.
+func (c *MyCallback) sinkFor(mr *api.MessageResponse) error {
+	if mr.IsFinal {
+		return c.session.sink.OnFinal(mr)
+	}
+	return c.session.sink.OnInterim(mr)
+}
+
+func (c *MyCallback) Open(or *api.OpenResponse) error {
+	fmt.Println("\n[Listen] connection opened")
+	return nil
+}
+
+func (c *MyCallback) Metadata(md *api.MetadataResponse) error {
+	// handle the metadata
+	fmt.Printf("\n[Metadata] Received\n")
+	fmt.Printf("Metadata.RequestID: %s\n", md.RequestID)
+	fmt.Printf("Metadata.Channels: %d\n", md.Channels)
+	fmt.Printf("Metadata.Created: %s\n\n", md.Created)
+
+	if err := c.session.sink.OnMetadata(md); err != nil {
+		fmt.Println("Error writing metadata to transcript sink:", err)
+	}
+	return nil
+}
+
+func (c *MyCallback) SpeechStarted(ssr *api.SpeechStartedResponse) error {
+	return nil
+}
+
+func (c *MyCallback) UtteranceEnd(ur *api.UtteranceEndResponse) error {
+	if err := c.session.sink.OnUtteranceEnd(ur); err != nil {
+		fmt.Println("Error writing utterance end to transcript sink:", err)
+	}
+
+	sentences := c.session.aggregator.flushAll()
+	if len(sentences) == 0 {
+		fmt.Printf("\n[UtteranceEnd] Received\n")
+		return nil
+	}
+
+	for key, sentence := range sentences {
+		fmt.Printf("\n[UtteranceEnd speaker %d, channel %d]: %s\n", key.Speaker, key.Channel, sentence)
+		c.socket.WriteJSON(transcriptMessage{
+			Type:    "transcript",
+			Speaker: key.Speaker,
+			Channel: key.Channel,
+			IsFinal: true,
+			Text:    sentence,
+		})
+	}
+
+	return nil
+}
+
+func (c *MyCallback) Close(cr *api.CloseResponse) error {
+	fmt.Println("\n[Listen] connection closed")
+	c.session.triggerReconnect()
+	return nil
+}
+
+func (c *MyCallback) Error(er *api.ErrorResponse) error {
+	// handle the error
+	fmt.Printf("\n[Error] Received\n")
+	fmt.Printf("Error.Type: %s\n", er.ErrCode)
+	fmt.Printf("Error.Message: %s\n", er.ErrMsg)
+	fmt.Printf("Error.Description: %s\n\n", er.Description)
+
+	c.session.triggerReconnect()
+
+	return nil
+}
+
+func (c *MyCallback) UnhandledEvent(byData []byte) error {
+	fmt.Printf("\n[Listen] Unhandled event: %s\n", string(byData))
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+// TranscriptSink receives the raw Deepgram events for a session in parallel
+// with the browser, so a session can be recorded or fed into a downstream
+// pipeline without changing how it's echoed to the client. MyCallback
+// delegates to whichever sink the server was configured with; the default
+// is a noopSink.
+type TranscriptSink interface {
+	OnInterim(mr *api.MessageResponse) error
+	OnFinal(mr *api.MessageResponse) error
+	OnUtteranceEnd(ur *api.UtteranceEndResponse) error
+	OnMetadata(md *api.MetadataResponse) error
+	Close() error
+}
+
+// noopSink is the default TranscriptSink; it discards everything.
+type noopSink struct{}
+
+func (noopSink) OnInterim(mr *api.MessageResponse) error           { return nil }
+func (noopSink) OnFinal(mr *api.MessageResponse) error             { return nil }
+func (noopSink) OnUtteranceEnd(ur *api.UtteranceEndResponse) error { return nil }
+func (noopSink) OnMetadata(md *api.MetadataResponse) error         { return nil }
+func (noopSink) Close() error                                      { return nil }
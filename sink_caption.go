@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+// captionFormat selects which subtitle dialect captionSink writes.
+type captionFormat int
+
+const (
+	captionFormatVTT captionFormat = iota
+	captionFormatSRT
+)
+
+// captionSink writes finalized transcripts as caption cues, using
+// word-level timings when Deepgram returned them and falling back to the
+// message's own Start/Duration otherwise.
+type captionSink struct {
+	mu     sync.Mutex
+	f      *os.File
+	format captionFormat
+	seq    int
+}
+
+func newCaptionSink(path string, format captionFormat) (*captionSink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating caption sink %q: %w", path, err)
+	}
+	if format == captionFormatVTT {
+		fmt.Fprintln(f, "WEBVTT")
+		fmt.Fprintln(f)
+	}
+	return &captionSink{f: f, format: format}, nil
+}
+
+func (s *captionSink) OnInterim(mr *api.MessageResponse) error { return nil }
+
+func (s *captionSink) OnFinal(mr *api.MessageResponse) error {
+	if len(mr.Channel.Alternatives) == 0 {
+		return nil
+	}
+	alt := mr.Channel.Alternatives[0]
+	text := strings.TrimSpace(alt.Transcript)
+	if text == "" {
+		return nil
+	}
+
+	start, end := mr.Start, mr.Start+mr.Duration
+	if len(alt.Words) > 0 {
+		start = alt.Words[0].Start
+		end = alt.Words[len(alt.Words)-1].End
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	if s.format == captionFormatSRT {
+		_, err := fmt.Fprintf(s.f, "%d\n%s --> %s\n%s\n\n", s.seq, formatSRTTimestamp(start), formatSRTTimestamp(end), text)
+		return err
+	}
+	_, err := fmt.Fprintf(s.f, "%s --> %s\n%s\n\n", formatVTTTimestamp(start), formatVTTTimestamp(end), text)
+	return err
+}
+
+func (s *captionSink) OnUtteranceEnd(ur *api.UtteranceEndResponse) error { return nil }
+
+func (s *captionSink) OnMetadata(md *api.MetadataResponse) error { return nil }
+
+func (s *captionSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+func formatVTTTimestamp(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	sec := d / time.Second
+	d -= sec * time.Second
+	ms := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, sec, ms)
+}
+
+func formatSRTTimestamp(seconds float64) string {
+	return strings.Replace(formatVTTTimestamp(seconds), ".", ",", 1)
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestFormatVTTTimestamp(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0, "00:00:00.000"},
+		{1.5, "00:00:01.500"},
+		{61.25, "00:01:01.250"},
+		{3661.001, "01:01:01.001"},
+	}
+
+	for _, tt := range tests {
+		if got := formatVTTTimestamp(tt.seconds); got != tt.want {
+			t.Errorf("formatVTTTimestamp(%v) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSRTTimestamp(t *testing.T) {
+	if got, want := formatSRTTimestamp(61.25), "00:01:01,250"; got != want {
+		t.Errorf("formatSRTTimestamp(61.25) = %q, want %q", got, want)
+	}
+}
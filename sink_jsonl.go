@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+// jsonlRecord is one line written by jsonlSink: the full MessageResponse (or
+// other event) plus enough envelope to tell records apart on replay.
+type jsonlRecord struct {
+	Kind      string      `json:"kind"`
+	Timestamp time.Time   `json:"timestamp"`
+	Payload   interface{} `json:"payload"`
+}
+
+// jsonlSink appends each interim, final, utterance-end and metadata event to
+// a single .jsonl file, capturing the full MessageResponse so a session can
+// be replayed later. The file is not rotated.
+type jsonlSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	f   *os.File
+}
+
+// newJSONLSink opens (creating if necessary) path for appending.
+func newJSONLSink(path string) (*jsonlSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening jsonl sink %q: %w", path, err)
+	}
+	return &jsonlSink{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (s *jsonlSink) write(kind string, payload interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(jsonlRecord{Kind: kind, Timestamp: time.Now(), Payload: payload})
+}
+
+func (s *jsonlSink) OnInterim(mr *api.MessageResponse) error {
+	return s.write("interim", mr)
+}
+
+func (s *jsonlSink) OnFinal(mr *api.MessageResponse) error {
+	return s.write("final", mr)
+}
+
+func (s *jsonlSink) OnUtteranceEnd(ur *api.UtteranceEndResponse) error {
+	return s.write("utterance_end", ur)
+}
+
+func (s *jsonlSink) OnMetadata(md *api.MetadataResponse) error {
+	return s.write("metadata", md)
+}
+
+func (s *jsonlSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+// webhookQueueSize bounds how many pending final transcripts webhookSink
+// will hold while posting. Message delivery to the browser/other sinks
+// happens on the SDK's single listen-read goroutine, so OnFinal must never
+// block on the network; once the queue is full, further finals are dropped
+// rather than applying backpressure to that goroutine.
+const webhookQueueSize = 32
+
+// webhookSink POSTs each final transcript to a user-configured URL, signing
+// the body so the receiver can verify it came from us. Posting happens on a
+// background worker so a slow or unresponsive endpoint can't stall
+// transcript processing.
+type webhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+
+	queue chan []byte
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newWebhookSink(url, secret string) *webhookSink {
+	s := &webhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan []byte, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.worker()
+	return s
+}
+
+func (s *webhookSink) OnInterim(mr *api.MessageResponse) error { return nil }
+
+func (s *webhookSink) OnFinal(mr *api.MessageResponse) error {
+	body, err := json.Marshal(mr)
+	if err != nil {
+		return fmt.Errorf("marshaling webhook payload: %w", err)
+	}
+
+	select {
+	case s.queue <- body:
+	default:
+		return fmt.Errorf("webhook queue full, dropping final transcript")
+	}
+	return nil
+}
+
+func (s *webhookSink) OnUtteranceEnd(ur *api.UtteranceEndResponse) error { return nil }
+
+func (s *webhookSink) OnMetadata(md *api.MetadataResponse) error { return nil }
+
+// Close stops accepting new posts and waits for the worker to drain
+// whatever is already queued.
+func (s *webhookSink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return nil
+}
+
+// worker posts queued bodies one at a time until Close drains the queue.
+func (s *webhookSink) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case body := <-s.queue:
+			if err := s.post(body); err != nil {
+				fmt.Println("Error posting to webhook:", err)
+			}
+		case <-s.done:
+			for {
+				select {
+				case body := <-s.queue:
+					if err := s.post(body); err != nil {
+						fmt.Println("Error posting to webhook:", err)
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// post sends body to the configured URL with an HMAC-SHA256 signature of
+// the body (hex-encoded) in the X-Deepgram-Signature header, mirroring the
+// pattern used by most webhook providers.
+func (s *webhookSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(s.secret) > 0 {
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		req.Header.Set("X-Deepgram-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
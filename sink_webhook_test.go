@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/deepgram/deepgram-go-sdk/pkg/api/live/v1/interfaces"
+)
+
+func TestWebhookSinkSignsAndPostsFinals(t *testing.T) {
+	const secret = "shh"
+
+	received := make(chan struct {
+		body []byte
+		sig  string
+	}, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- struct {
+			body []byte
+			sig  string
+		}{body, r.Header.Get("X-Deepgram-Signature")}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sink := newWebhookSink(srv.URL, secret)
+
+	mr := &api.MessageResponse{IsFinal: true}
+	if err := sink.OnFinal(mr); err != nil {
+		t.Fatalf("OnFinal() error = %v", err)
+	}
+
+	// Close drains the queue, so by the time it returns the POST above has
+	// landed.
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case got := <-received:
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(got.body)
+		want := hex.EncodeToString(mac.Sum(nil))
+		if got.sig != want {
+			t.Errorf("X-Deepgram-Signature = %q, want %q", got.sig, want)
+		}
+	default:
+		t.Fatal("webhook endpoint never received a request")
+	}
+}
+
+func TestWebhookSinkOnFinalDoesNotBlockWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block // hang until the test releases it
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer func() {
+		close(block)
+		srv.Close()
+	}()
+
+	sink := newWebhookSink(srv.URL, "")
+	defer func() {
+		go sink.Close() // worker is stuck on the in-flight request until block closes
+	}()
+
+	// The worker can drain at most one item (the one it's permanently
+	// blocked posting); everything past the queue's capacity must be
+	// rejected rather than blocking the caller, so pushing well beyond
+	// that capacity is guaranteed to produce at least one error.
+	mr := &api.MessageResponse{IsFinal: true}
+	sawQueueFullErr := false
+	for i := 0; i < webhookQueueSize*3; i++ {
+		if err := sink.OnFinal(mr); err != nil {
+			sawQueueFullErr = true
+			break
+		}
+	}
+	if !sawQueueFullErr {
+		t.Fatal("OnFinal() never reported a full queue; it may be blocking instead of dropping")
+	}
+}
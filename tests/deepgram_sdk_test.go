@@ -49,6 +49,25 @@ func (c MyCallback) Message(mr *api.MessageResponse) error {
 	return nil
 }
 
+func (c MyCallback) Open(or *api.OpenResponse) error {
+	fmt.Printf("\n[Open] Received\n")
+	return nil
+}
+
+func (c MyCallback) SpeechStarted(ssr *api.SpeechStartedResponse) error {
+	return nil
+}
+
+func (c MyCallback) Close(cr *api.CloseResponse) error {
+	fmt.Printf("\n[Close] Received\n")
+	return nil
+}
+
+func (c MyCallback) UnhandledEvent(byData []byte) error {
+	fmt.Printf("\n[UnhandledEvent] Received: %s\n", string(byData))
+	return nil
+}
+
 func (c MyCallback) Metadata(md *api.MetadataResponse) error {
 	// handle the metadata
 	fmt.Printf("\n[Metadata] Received\n")
@@ -62,7 +81,7 @@ func (c MyCallback) Error(er *api.ErrorResponse) error {
 	// handle the error
 	fmt.Printf("\n[Error] Received\n")
 	fmt.Printf("Error.Type: %s\n", er.Type)
-	fmt.Printf("Error.ErrCode: %s\n", er.Message)
+	fmt.Printf("Error.Message: %s\n", er.ErrMsg)
 	fmt.Printf("Error.Description: %s\n\n", er.Description)
 	return nil
 }
@@ -104,7 +123,7 @@ func TestDeepgramLiveTranscription(t *testing.T) {
 	}
 
 	// Create a Deepgram client
-	dgClient, err := client.NewWithDefaults(ctx, transcriptOptions, callback)
+	dgClient, err := client.NewWithDefaults(ctx, &transcriptOptions, callback)
 	if err != nil {
 		t.Fatalf("ERROR creating LiveTranscription connection: %v", err)
 	}
@@ -119,7 +138,7 @@ func TestDeepgramLiveTranscription(t *testing.T) {
 
 	// Connect the websocket to Deepgram
 	bConnected := dgClient.Connect()
-	if bConnected == nil {
+	if !bConnected {
 		t.Fatal("Client.Connect failed")
 	}
 